@@ -0,0 +1,308 @@
+package swo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/target/goalert/swo/swogrp"
+)
+
+// checkpointPhase tracks where DoExecute is in the switchover process, so a
+// restart can fast-forward instead of starting over.
+type checkpointPhase string
+
+const (
+	checkpointScanning    checkpointPhase = "scanning"
+	checkpointInitialSync checkpointPhase = "initial_sync"
+	checkpointCatchup     checkpointPhase = "catchup"
+	checkpointPaused      checkpointPhase = "paused"
+	checkpointFinalSync   checkpointPhase = "final_sync"
+)
+
+// checkpointFlushInterval and checkpointFlushRows bound how often a
+// checkpointWriter persists progress: a call to Advance flushes once
+// checkpointFlushInterval has passed since the last flush or once
+// checkpointFlushRows calls to Advance (one per sync unit) have been made
+// since it, whichever comes first. Copied rows are not counted, and
+// nothing is flushed between Advance calls; progress recorded since the
+// last flush is lost on a crash.
+const (
+	checkpointFlushInterval = 5 * time.Second
+	checkpointFlushRows     = 5000
+)
+
+// phaseRowTable and phaseRowChunk identify the sentinel row in
+// switchover_checkpoint that holds the current overall phase, distinct
+// from the per-(table, chunk) progress rows.
+const (
+	phaseRowTable = ""
+	phaseRowChunk = ""
+)
+
+// chunkCheckpoint is the persisted completion state of a single sync unit
+// (one table, or one row-range chunk of a large table, per splitTableRanges).
+// ChunkStart is the chunk's lower-bound ID (formatted as text), or "" for a
+// table that was synced as a single unit.
+type chunkCheckpoint struct {
+	TableName  string
+	ChunkStart string
+	Done       bool
+}
+
+// syncCheckpoint is the full persisted state of an in-progress switchover,
+// loaded from switchover_checkpoint at the start of InitialSync.
+type syncCheckpoint struct {
+	Phase  checkpointPhase
+	Chunks map[string]map[string]chunkCheckpoint // table name -> chunk start -> state
+}
+
+// tableDone reports whether every chunk recorded for table is marked done.
+// A table with no recorded chunks at all is considered not done (it has
+// not been attempted yet).
+func (cp *syncCheckpoint) tableDone(table string) bool {
+	chunks, ok := cp.Chunks[table]
+	if !ok || len(chunks) == 0 {
+		return false
+	}
+	for _, c := range chunks {
+		if !c.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// chunkDone reports whether the specific chunk of table starting at
+// chunkStart has already been synced.
+func (cp *syncCheckpoint) chunkDone(table, chunkStart string) bool {
+	chunks, ok := cp.Chunks[table]
+	if !ok {
+		return false
+	}
+	return chunks[chunkStart].Done
+}
+
+// ensureCheckpointTable creates the switchover_checkpoint table on the
+// source DB if it does not already exist.
+func ensureCheckpointTable(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, `
+		create table if not exists switchover_checkpoint (
+			table_name text not null,
+			chunk_start text not null default '',
+			done boolean not null default false,
+			phase text not null default 'scanning',
+			updated_at timestamptz not null default now(),
+			primary key (table_name, chunk_start)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create checkpoint table: %w", err)
+	}
+	return nil
+}
+
+// loadCheckpoint reads the persisted switchover progress, if any. It
+// returns a checkpoint with phase checkpointScanning and no chunk entries
+// if nothing has been recorded yet (a fresh run).
+func loadCheckpoint(ctx context.Context, conn *pgx.Conn) (*syncCheckpoint, error) {
+	if err := ensureCheckpointTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	cp := &syncCheckpoint{Phase: checkpointScanning, Chunks: make(map[string]map[string]chunkCheckpoint)}
+
+	var table, chunkStart, phase string
+	var done bool
+	_, err := conn.QueryFunc(ctx,
+		"select table_name, chunk_start, done, phase from switchover_checkpoint",
+		nil, []interface{}{&table, &chunkStart, &done, &phase},
+		func(pgx.QueryFuncRow) error {
+			if table == phaseRowTable && chunkStart == phaseRowChunk {
+				cp.Phase = checkpointPhase(phase)
+				return nil
+			}
+			if cp.Chunks[table] == nil {
+				cp.Chunks[table] = make(map[string]chunkCheckpoint)
+			}
+			cp.Chunks[table][chunkStart] = chunkCheckpoint{TableName: table, ChunkStart: chunkStart, Done: done}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query checkpoint: %w", err)
+	}
+
+	return cp, nil
+}
+
+// resetCheckpoint clears all persisted switchover progress, forcing the
+// next DoExecute to start from scratch.
+func resetCheckpoint(ctx context.Context, conn *pgx.Conn) error {
+	if err := ensureCheckpointTable(ctx, conn); err != nil {
+		return err
+	}
+	_, err := conn.Exec(ctx, "delete from switchover_checkpoint")
+	if err != nil {
+		return fmt.Errorf("reset checkpoint: %w", err)
+	}
+	return nil
+}
+
+// setCheckpointPhase records the overall phase of the switchover, for
+// `swo status` to report even once every table has finished its initial
+// sync (when per-table progress rows no longer change).
+func setCheckpointPhase(ctx context.Context, conn *pgx.Conn, phase checkpointPhase) error {
+	if err := ensureCheckpointTable(ctx, conn); err != nil {
+		return err
+	}
+	_, err := conn.Exec(ctx, `
+		insert into switchover_checkpoint (table_name, chunk_start, done, phase, updated_at)
+		values ($1, $2, true, $3, now())
+		on conflict (table_name, chunk_start) do update
+		set phase = $3, updated_at = now()
+	`, phaseRowTable, phaseRowChunk, phase)
+	if err != nil {
+		return fmt.Errorf("set checkpoint phase: %w", err)
+	}
+	return nil
+}
+
+// Status is the result of inspecting switchover_checkpoint, for use by a
+// `swo status` command.
+type Status struct {
+	Phase  checkpointPhase
+	Chunks []chunkCheckpoint
+}
+
+// GetStatus reports the current persisted switchover progress. It is the
+// intended entry point for a `swo status` CLI command and for exposing
+// progress over the admin API, neither of which live in this package --
+// this tree doesn't have a cmd/goalert or graphql2 package to wire them
+// up in, so that wiring still needs to be added alongside whichever of
+// those packages ends up calling GetStatus.
+func GetStatus(ctx context.Context, conn *pgx.Conn) (*Status, error) {
+	cp, err := loadCheckpoint(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	}
+
+	s := &Status{Phase: cp.Phase}
+	for _, chunks := range cp.Chunks {
+		for _, c := range chunks {
+			s.Chunks = append(s.Chunks, c)
+		}
+	}
+	return s, nil
+}
+
+// ResetCheckpoint clears persisted switchover progress, for use by a
+// `swo reset-checkpoint` admin command. It refuses to run while a
+// switchover is actively in progress.
+func ResetCheckpoint(ctx context.Context, conn *pgx.Conn) error {
+	var stat string
+	err := conn.QueryRow(ctx, "select current_state from switchover_state").Scan(&stat)
+	if err != nil {
+		return fmt.Errorf("get switchover state: %w", err)
+	}
+	if stat == "in_progress" {
+		return fmt.Errorf("refusing to reset checkpoint: switchover is in_progress")
+	}
+
+	return resetCheckpoint(ctx, conn)
+}
+
+// checkpointWriter batches per-chunk checkpoint updates so the source DB
+// is not thrashed with a write after every synced unit; it flushes after
+// checkpointFlushRows calls to Advance or checkpointFlushInterval,
+// whichever comes first.
+type checkpointWriter struct {
+	conn *pgx.Conn
+
+	mu        sync.Mutex
+	pending   map[[2]string]chunkCheckpoint
+	phase     checkpointPhase
+	rowsSince int
+	lastFlush time.Time
+}
+
+// newCheckpointWriter returns a checkpointWriter that persists to conn.
+func newCheckpointWriter(conn *pgx.Conn) *checkpointWriter {
+	return &checkpointWriter{
+		conn:      conn,
+		pending:   make(map[[2]string]chunkCheckpoint),
+		lastFlush: time.Now(),
+	}
+}
+
+// Advance records that the (table, chunkStart) sync unit has completed,
+// flushing to the DB if the batch is due.
+func (w *checkpointWriter) Advance(ctx context.Context, phase checkpointPhase, table, chunkStart string, done bool) error {
+	w.mu.Lock()
+	w.phase = phase
+	w.pending[[2]string{table, chunkStart}] = chunkCheckpoint{TableName: table, ChunkStart: chunkStart, Done: done}
+	w.rowsSince++
+	due := w.rowsSince >= checkpointFlushRows || time.Since(w.lastFlush) >= checkpointFlushInterval
+	w.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return w.Flush(ctx)
+}
+
+// Flush persists any pending checkpoint updates immediately. The mutex is
+// held for the whole call, including the SendBatch round-trip, since conn
+// may be shared with other callers of Advance/Flush (w.conn is not safe
+// for concurrent use) and a map swap alone would only protect the
+// pending map, not the network call itself.
+func (w *checkpointWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	var batch pgx.Batch
+	for _, cp := range w.pending {
+		batch.Queue(`
+			insert into switchover_checkpoint (table_name, chunk_start, done, phase, updated_at)
+			values ($1, $2, $3, $4, now())
+			on conflict (table_name, chunk_start) do update
+			set done = $3, phase = $4, updated_at = now()
+		`, cp.TableName, cp.ChunkStart, cp.Done, w.phase)
+	}
+
+	if err := w.conn.SendBatch(ctx, &batch).Close(); err != nil {
+		return fmt.Errorf("flush checkpoint: %w", err)
+	}
+
+	w.pending = make(map[[2]string]chunkCheckpoint)
+	w.rowsSince = 0
+	w.lastFlush = time.Now()
+	return nil
+}
+
+// resumeTables drops tables whose every chunk is already marked done in
+// cp from the work list, so a restarted InitialSync does not re-scan
+// them. Tables that are only partially done are kept in the list so that
+// InitialSync can skip their already-done chunks individually via
+// cp.chunkDone, instead of re-copying the whole table.
+func resumeTables(ctx context.Context, tables []Table, cp *syncCheckpoint) []Table {
+	if cp == nil || len(cp.Chunks) == 0 {
+		return tables
+	}
+
+	var remaining []Table
+	for _, t := range tables {
+		if cp.tableDone(t.Name) {
+			swogrp.Progressf(ctx, "skipping already-synced table %s", t.Name)
+			continue
+		}
+		remaining = append(remaining, t)
+	}
+	return remaining
+}
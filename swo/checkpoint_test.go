@@ -0,0 +1,68 @@
+package swo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSyncCheckpointTableDone(t *testing.T) {
+	cp := &syncCheckpoint{Chunks: map[string]map[string]chunkCheckpoint{
+		"alerts": {
+			"":  {Done: true},
+			"1": {Done: true},
+		},
+		"services": {
+			"": {Done: false},
+		},
+	}}
+
+	if !cp.tableDone("alerts") {
+		t.Error("tableDone(alerts) = false, want true (all chunks done)")
+	}
+	if cp.tableDone("services") {
+		t.Error("tableDone(services) = true, want false (chunk not done)")
+	}
+	if cp.tableDone("unknown") {
+		t.Error("tableDone(unknown) = true, want false (no recorded chunks)")
+	}
+}
+
+func TestSyncCheckpointChunkDone(t *testing.T) {
+	cp := &syncCheckpoint{Chunks: map[string]map[string]chunkCheckpoint{
+		"alerts": {
+			"100": {Done: true},
+			"200": {Done: false},
+		},
+	}}
+
+	if !cp.chunkDone("alerts", "100") {
+		t.Error("chunkDone(alerts, 100) = false, want true")
+	}
+	if cp.chunkDone("alerts", "200") {
+		t.Error("chunkDone(alerts, 200) = true, want false")
+	}
+	if cp.chunkDone("alerts", "300") {
+		t.Error("chunkDone(alerts, 300) = true, want false (no such chunk)")
+	}
+	if cp.chunkDone("unknown", "100") {
+		t.Error("chunkDone(unknown, 100) = true, want false (no such table)")
+	}
+}
+
+func TestResumeTables(t *testing.T) {
+	tables := []Table{{Name: "alerts"}, {Name: "services"}, {Name: "users"}}
+	cp := &syncCheckpoint{Chunks: map[string]map[string]chunkCheckpoint{
+		"alerts":   {"": {Done: true}},
+		"services": {"": {Done: false}},
+	}}
+
+	remaining := resumeTables(context.Background(), tables, cp)
+	if len(remaining) != 2 {
+		t.Fatalf("resumeTables returned %d tables, want 2 (alerts dropped)", len(remaining))
+	}
+	for _, tb := range remaining {
+		if tb.Name == "alerts" {
+			t.Error("resumeTables kept a fully-done table")
+		}
+	}
+}
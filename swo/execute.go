@@ -40,7 +40,21 @@ func WaitForRunningTx(ctx context.Context, oldConn *pgx.Conn) error {
 	return nil
 }
 
-func (m *Manager) DoExecute(ctx context.Context) error {
+// ExecuteOption configures a call to Manager.DoExecute.
+type ExecuteOption func(*executeOptions)
+
+type executeOptions struct{ dryRun bool }
+
+// WithDryRun runs DoExecute through Verify and then stops instead of
+// running the stop-the-world final sync and flipping switchover_state to
+// use_next_db, so operators can rehearse a cutover on production traffic
+// without committing to it. Destination triggers are re-enabled
+// afterwards; rows already copied to the destination are not removed.
+func WithDryRun() ExecuteOption {
+	return func(o *executeOptions) { o.dryRun = true }
+}
+
+func (m *Manager) DoExecute(ctx context.Context, opts ...ExecuteOption) error {
 	/*
 		- initial sync
 		- loop until few changes
@@ -49,6 +63,10 @@ func (m *Manager) DoExecute(ctx context.Context) error {
 		- execute proposal
 
 	*/
+	var o executeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	return m.withConnFromBoth(ctx, func(ctx context.Context, oldConn, newConn *pgx.Conn) error {
 		swogrp.Progressf(ctx, "scanning tables...")
@@ -87,11 +105,38 @@ func (m *Manager) DoExecute(ctx context.Context) error {
 			return fmt.Errorf("read row IDs: %w", err)
 		}
 
+		if err = setCheckpointPhase(ctx, oldConn, checkpointCatchup); err != nil {
+			return fmt.Errorf("set checkpoint phase: %w", err)
+		}
+
 		err = SyncChanges(ctx, rt, oldConn, newConn)
+		if errors.Is(err, ErrPaused) {
+			swogrp.Progressf(ctx, "switchover paused; call ResumeSwitchover to continue")
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("sync changes: %w", err)
 		}
 
+		if o.dryRun {
+			swogrp.Progressf(ctx, "dry-run: verifying instead of cutting over")
+			report, err := m.Verify(ctx)
+			if err != nil {
+				return fmt.Errorf("verify: %w", err)
+			}
+			swogrp.Progressf(ctx, "dry-run: %d table(s) checked, %d mismatch(es) found", len(report.Tables), report.MismatchCount())
+
+			// only the destination triggers disabled earlier in this call
+			// are restored; rows already copied to the destination and the
+			// source change log are left in place, and switchover_state is
+			// not changed.
+			swogrp.Progressf(ctx, "dry-run: re-enabling destination triggers")
+			if err = EnableTriggers(ctx, tables, newConn); err != nil {
+				return fmt.Errorf("enable triggers: %w", err)
+			}
+			return nil
+		}
+
 		swogrp.Progressf(ctx, "pausing")
 		err = m.grp.Pause(ctx)
 		if err != nil {
@@ -135,6 +180,16 @@ func (m *Manager) DoExecute(ctx context.Context) error {
 
 func SyncChanges(ctx context.Context, rt *rowTracker, oldConn, newConn *pgx.Conn) error {
 	for ctx.Err() == nil {
+		// no tx is open here, so this is a safe point to honor a pause request
+		paused, err := isPaused(ctx, oldConn)
+		if err != nil {
+			return fmt.Errorf("check pause state: %w", err)
+		}
+		if paused {
+			swogrp.Progressf(ctx, "sync paused")
+			return ErrPaused
+		}
+
 		// sync in a loop until DB is up-to-date
 		s := time.Now()
 		n, pend, err := LoopSync(ctx, rt, oldConn, newConn)
@@ -221,6 +276,20 @@ func FinalSync(ctx context.Context, rt *rowTracker, srcConn, dstConn *pgx.Conn)
 		return fmt.Errorf("sync changes: %w", err)
 	}
 
+	if err = setCheckpointPhase(ctx, srcConn, checkpointFinalSync); err != nil {
+		return fmt.Errorf("set checkpoint phase: %w", err)
+	}
+
+	return retryTx(ctx, "final sync", func(ctx context.Context) error {
+		return finalSyncAttempt(ctx, rt, srcConn, dstConn, seqNames, &seqRead)
+	})
+}
+
+// finalSyncAttempt runs one attempt of the stop-the-world cutover in fresh
+// src/dst transactions. pg_advisory_xact_lock is scoped to the
+// transaction, so it cannot outlive the rollback a retry performs -- every
+// attempt (including retries) reacquires it from scratch.
+func finalSyncAttempt(ctx context.Context, rt *rowTracker, srcConn, dstConn *pgx.Conn, seqNames []string, seqRead *pgx.Batch) error {
 	srcTx, err := srcConn.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("begin src: %w", err)
@@ -257,7 +326,7 @@ func FinalSync(ctx context.Context, rt *rowTracker, srcConn, dstConn *pgx.Conn)
 		return fmt.Errorf("sync change log: %w", err)
 	}
 
-	res := srcTx.SendBatch(ctx, &seqRead)
+	res := srcTx.SendBatch(ctx, seqRead)
 	var setSeq pgx.Batch
 	for _, name := range seqNames {
 		var last int64
@@ -300,28 +369,43 @@ func FinalSync(ctx context.Context, rt *rowTracker, srcConn, dstConn *pgx.Conn)
 }
 
 func LoopSync(ctx context.Context, rt *rowTracker, srcConn, dstConn *pgx.Conn) (ok, pend int, err error) {
-	srcTx, dstTx, err := syncTx(ctx, srcConn, dstConn)
-	if err != nil {
-		return 0, 0, fmt.Errorf("sync tx: %w", err)
-	}
-	defer srcTx.Rollback(ctx)
-	defer dstTx.Rollback(ctx)
+	var ids []int
+	err = retryTx(ctx, "loop sync", func(ctx context.Context) error {
+		srcTx, dstTx, txErr := syncTx(ctx, srcConn, dstConn)
+		if txErr != nil {
+			return fmt.Errorf("sync tx: %w", txErr)
+		}
+		defer srcTx.Rollback(ctx)
+		defer dstTx.Rollback(ctx)
 
-	ids, err := syncChangeLog(ctx, rt, srcTx, dstTx)
-	if err != nil {
-		return 0, len(ids), fmt.Errorf("sync change log: %w", err)
-	}
+		ids, txErr = syncChangeLog(ctx, rt, srcTx, dstTx)
+		if txErr != nil {
+			rt.Rollback()
+			pend = len(ids)
+			return fmt.Errorf("sync change log: %w", txErr)
+		}
 
-	err = srcTx.Commit(ctx)
-	if err != nil {
-		return len(ids), 0, fmt.Errorf("commit src: %w", err)
-	}
+		txErr = srcTx.Commit(ctx)
+		if txErr != nil {
+			rt.Rollback()
+			return fmt.Errorf("commit src: %w", txErr)
+		}
 
-	err = dstTx.Commit(ctx)
+		txErr = dstTx.Commit(ctx)
+		if txErr != nil {
+			rt.Rollback()
+			return fmt.Errorf("commit dst: %w", txErr)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return 0, len(ids), fmt.Errorf("commit dst: %w", err)
+		return 0, pend, err
 	}
 
+	// both transactions are committed, so the change_log cleanup is not
+	// retried; a failure here reports the replicated count, which
+	// SyncChanges treats as fatal instead of rolling back rt
 	_, err = srcConn.Exec(ctx, "DELETE FROM change_log WHERE id = any($1)", sqlutil.IntArray(ids))
 	if err != nil {
 		return len(ids), 0, fmt.Errorf("update change log: %w", err)
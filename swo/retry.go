@@ -0,0 +1,81 @@
+package swo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/target/goalert/swo/swogrp"
+)
+
+// retryableSQLStates are Postgres error codes that are expected to occur
+// transiently on a busy production database and should be retried rather
+// than treated as fatal:
+//
+//   - 40001 serialization_failure
+//   - 40P01 deadlock_detected
+//   - 55P03 lock_not_available
+//   - 57014 query_canceled (used for statement/lock_timeout cancellations)
+var retryableSQLStates = map[string]bool{
+	"40001": true,
+	"40P01": true,
+	"55P03": true,
+	"57014": true,
+}
+
+// isRetryableErr reports whether err is a Postgres error that is safe to
+// retry (a serialization failure, deadlock, or lock timeout), as opposed
+// to a fatal error that should abort the switchover.
+func isRetryableErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return retryableSQLStates[pgErr.Code]
+}
+
+const (
+	retryMaxAttempts = 8
+	retryBaseDelay   = 100 * time.Millisecond
+	retryMaxDelay    = 5 * time.Second
+)
+
+// retryTx runs fn, retrying with jittered exponential backoff (capped at
+// retryMaxDelay, up to retryMaxAttempts) whenever fn returns a retryable
+// error per isRetryableErr. Any non-retryable error is returned
+// immediately. Progress is reported via swogrp.Progressf so operators can
+// see that a retry (rather than a hang) is in progress.
+func retryTx(ctx context.Context, label string, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; attempt <= retryMaxAttempts; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if !isRetryableErr(err) {
+			return err
+		}
+		if attempt == retryMaxAttempts {
+			break
+		}
+
+		delay := retryBaseDelay * time.Duration(1<<uint(attempt-1))
+		delay = time.Duration(float64(delay) * (0.5 + rand.Float64()))
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+
+		swogrp.Progressf(ctx, "%s: retrying after transient error (attempt %d/%d): %v", label, attempt, retryMaxAttempts, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return fmt.Errorf("%s: giving up after %d attempts: %w", label, retryMaxAttempts, err)
+}
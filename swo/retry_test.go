@@ -0,0 +1,37 @@
+package swo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestIsRetryableErr(t *testing.T) {
+	for _, code := range []string{"40001", "40P01", "55P03", "57014"} {
+		err := &pgconn.PgError{Code: code}
+		if !isRetryableErr(err) {
+			t.Errorf("isRetryableErr(%s) = false, want true", code)
+		}
+	}
+
+	for _, code := range []string{"23505", "42601", ""} {
+		err := &pgconn.PgError{Code: code}
+		if isRetryableErr(err) {
+			t.Errorf("isRetryableErr(%s) = true, want false", code)
+		}
+	}
+
+	if isRetryableErr(errors.New("not a pg error")) {
+		t.Error("isRetryableErr(non-pg error) = true, want false")
+	}
+	if isRetryableErr(nil) {
+		t.Error("isRetryableErr(nil) = true, want false")
+	}
+
+	wrapped := fmt.Errorf("final sync: %w", &pgconn.PgError{Code: "40001"})
+	if !isRetryableErr(wrapped) {
+		t.Error("isRetryableErr(wrapped retryable error) = false, want true")
+	}
+}
@@ -0,0 +1,363 @@
+package swo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/target/goalert/swo/swogrp"
+)
+
+// envSyncConcurrency is the name of the environment variable used to
+// override the number of initial-sync workers. If unset or invalid,
+// defaultSyncConcurrency is used.
+const envSyncConcurrency = "SWO_SYNC_CONCURRENCY"
+
+const defaultSyncConcurrency = 4
+
+// syncConcurrency returns the configured number of initial-sync workers.
+func syncConcurrency() int {
+	v := os.Getenv(envSyncConcurrency)
+	if v == "" {
+		return defaultSyncConcurrency
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return defaultSyncConcurrency
+	}
+	return n
+}
+
+// chunkRowLimit is the approximate number of rows per COPY unit for large
+// tables, so that no single worker ties up the pool for the entire table.
+const chunkRowLimit = 100_000
+
+// syncUnit is a single piece of work for the initial-sync worker pool: all
+// or part of one table, copied as-is from the source to the destination.
+// chunkStart is the unit's checkpoint key: the chunk's lower-bound ID
+// (formatted as text) for a row-range unit, or "" for a table synced as a
+// single unit.
+type syncUnit struct {
+	table      Table
+	where      string // optional WHERE clause restricting the row range, or ""
+	chunkStart string
+}
+
+// InitialSync copies all rows for tables from oldConn to newConn.
+//
+// Tables are grouped into dependency levels (a table only appears in a
+// level once every table it has a foreign key to has already appeared in
+// an earlier level), and levels run one after another so that inserts
+// never violate a foreign key constraint. Within a level, sync units are
+// dispatched to a pool of syncConcurrency workers; large tables are split
+// into multiple row-range units by Table.IDCol so that a single huge
+// table cannot monopolize the pool. Each unit streams rows with
+// `COPY ... TO STDOUT` on the source and `COPY ... FROM STDIN` on the
+// destination, piping between them, instead of materializing JSON arrays.
+func (m *Manager) InitialSync(ctx context.Context, tables []Table, oldConn, newConn *pgx.Conn) error {
+	cp, err := loadCheckpoint(ctx, oldConn)
+	if err != nil {
+		return fmt.Errorf("load checkpoint: %w", err)
+	}
+	tables = resumeTables(ctx, tables, cp)
+
+	cw := newCheckpointWriter(oldConn)
+
+	levels, err := tableLevels(tables)
+	if err != nil {
+		return fmt.Errorf("order tables: %w", err)
+	}
+
+	for _, level := range levels {
+		var units []syncUnit
+		for _, t := range level {
+			if t.SkipSync() {
+				continue
+			}
+			ranges, err := splitTableRanges(ctx, t, oldConn)
+			if err != nil {
+				return fmt.Errorf("split table %s: %w", t.Name, err)
+			}
+			for _, r := range ranges {
+				if cp.chunkDone(t.Name, r.start) {
+					swogrp.Progressf(ctx, "skipping already-synced chunk %s[%s]", t.Name, r.start)
+					continue
+				}
+				// record the unit as not done before it runs, so a table with
+				// only some of its chunks finished is not taken as fully
+				// synced by resumeTables after a restart
+				if err := cw.Advance(ctx, checkpointInitialSync, t.Name, r.start, false); err != nil {
+					return fmt.Errorf("record chunk %s[%s]: %w", t.Name, r.start, err)
+				}
+				units = append(units, syncUnit{table: t, where: r.where, chunkStart: r.start})
+			}
+		}
+		if len(units) == 0 {
+			continue
+		}
+
+		if err := runSyncUnits(ctx, units, oldConn, newConn, cw); err != nil {
+			return fmt.Errorf("sync level: %w", err)
+		}
+	}
+
+	return cw.Flush(ctx)
+}
+
+// tableLevels groups tables into dependency waves: every table in level N
+// only references tables present in levels 0..N-1 (or nothing). Within a
+// wave, tables can be synced concurrently without risk of violating a
+// foreign key constraint on the destination.
+func tableLevels(tables []Table) ([][]Table, error) {
+	index := make(map[string]int, len(tables))
+	for i, t := range tables {
+		index[t.Name] = i
+	}
+
+	deps := make([][]int, len(tables))
+	for i, t := range tables {
+		for _, fk := range t.ForeignKeys() {
+			j, ok := index[fk]
+			if !ok || j == i {
+				continue
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	level := make([]int, len(tables))
+	for i := range tables {
+		lvl, err := tableLevel(i, deps, level, make(map[int]bool))
+		if err != nil {
+			return nil, err
+		}
+		level[i] = lvl
+	}
+
+	maxLevel := 0
+	for _, lvl := range level {
+		if lvl > maxLevel {
+			maxLevel = lvl
+		}
+	}
+
+	levels := make([][]Table, maxLevel+1)
+	for i, t := range tables {
+		levels[level[i]] = append(levels[level[i]], t)
+	}
+
+	return levels, nil
+}
+
+func tableLevel(i int, deps [][]int, memo []int, visiting map[int]bool) (int, error) {
+	if memo[i] > 0 {
+		return memo[i], nil
+	}
+	if visiting[i] {
+		return 0, fmt.Errorf("circular foreign key dependency")
+	}
+	visiting[i] = true
+
+	lvl := 0
+	for _, j := range deps[i] {
+		dl, err := tableLevel(j, deps, memo, visiting)
+		if err != nil {
+			return 0, err
+		}
+		if dl+1 > lvl {
+			lvl = dl + 1
+		}
+	}
+
+	visiting[i] = false
+	memo[i] = lvl
+	return lvl, nil
+}
+
+// tableRange is a single row-range chunk of a table, with a WHERE clause
+// restricting the range and a start key stable across restarts (the
+// chunk's lower-bound ID) that doubles as its checkpoint identity.
+type tableRange struct {
+	where string
+	start string
+}
+
+// splitTableRanges returns a set of row ranges that partition table by
+// Table.IDCol into pieces of roughly chunkRowLimit rows each. A single
+// range with an empty WHERE clause and start key is returned for tables
+// that are small enough, or that have no usable integer ID column to
+// split on (in which case a large table is logged rather than silently
+// synced as one unit, since that's a real loss of parallelism for it).
+func splitTableRanges(ctx context.Context, t Table, conn *pgx.Conn) ([]tableRange, error) {
+	if t.IDCol.Type != "integer" && t.IDCol.Type != "bigint" {
+		var count int64
+		if err := conn.QueryRow(ctx, fmt.Sprintf("select count(*) from %s", t.QuotedName())).Scan(&count); err != nil {
+			return nil, fmt.Errorf("measure table: %w", err)
+		}
+		if count > chunkRowLimit {
+			swogrp.Progressf(ctx, "table %s has %d rows but a %s id column, which can't be range-split; syncing as a single unit", t.Name, count, t.IDCol.Type)
+		}
+		return []tableRange{{}}, nil
+	}
+
+	var minID, maxID, count int64
+	err := conn.QueryRow(ctx, fmt.Sprintf(
+		"select count(*), coalesce(min(%s), 0), coalesce(max(%s), 0) from %s",
+		t.IDCol.Name, t.IDCol.Name, t.QuotedName(),
+	)).Scan(&count, &minID, &maxID)
+	if err != nil {
+		return nil, fmt.Errorf("measure table: %w", err)
+	}
+	if count <= chunkRowLimit {
+		return []tableRange{{}}, nil
+	}
+
+	span := maxID - minID + 1
+	numChunks := int(span / chunkRowLimit)
+	if numChunks < 1 {
+		numChunks = 1
+	}
+	chunkSize := span / int64(numChunks)
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+
+	var ranges []tableRange
+	for start := minID; start <= maxID; start += chunkSize {
+		end := start + chunkSize
+		startKey := strconv.FormatInt(start, 10)
+		if end > maxID+1 {
+			ranges = append(ranges, tableRange{
+				where: fmt.Sprintf("%s >= %d", t.IDCol.Name, start),
+				start: startKey,
+			})
+			break
+		}
+		ranges = append(ranges, tableRange{
+			where: fmt.Sprintf("%s >= %d and %s < %d", t.IDCol.Name, start, t.IDCol.Name, end),
+			start: startKey,
+		})
+	}
+
+	return ranges, nil
+}
+
+// runSyncUnits dispatches units to a pool of syncConcurrency workers,
+// canceling siblings and returning the first non-nil error encountered
+// (matching the doBatches error-aggregation pattern used elsewhere).
+//
+// oldConn and newConn are only used as templates for their connection
+// config: pgx.Conn is not safe for concurrent use, and both connections
+// are shared with the rest of the switchover for the whole DoExecute
+// call, so each worker dials its own dedicated pair instead of sharing
+// either one.
+func runSyncUnits(ctx context.Context, units []syncUnit, oldConn, newConn *pgx.Conn, cw *checkpointWriter) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := syncConcurrency()
+	if workers > len(units) {
+		workers = len(units)
+	}
+
+	unitCh := make(chan syncUnit)
+	errCh := make(chan error, workers)
+
+	oldCfg, newCfg := oldConn.Config(), newConn.Config()
+	for i := 0; i < workers; i++ {
+		go func() {
+			errCh <- syncWorker(ctx, unitCh, oldCfg, newCfg, cw)
+		}()
+	}
+
+	go func() {
+		defer close(unitCh)
+		for _, u := range units {
+			select {
+			case unitCh <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var firstErr error
+	for i := 0; i < workers; i++ {
+		if err := <-errCh; err != nil && firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	swogrp.Progressf(ctx, "synced %d unit(s)", len(units))
+	return nil
+}
+
+// syncWorker dials its own dedicated source/destination connection pair
+// (copied from oldCfg/newCfg so concurrent workers never share a
+// pgx.Conn) and processes units from unitCh until it is closed or an
+// error occurs.
+func syncWorker(ctx context.Context, unitCh <-chan syncUnit, oldCfg, newCfg *pgx.ConnConfig, cw *checkpointWriter) error {
+	oldConn, err := pgx.ConnectConfig(ctx, oldCfg.Copy())
+	if err != nil {
+		return fmt.Errorf("connect source: %w", err)
+	}
+	defer oldConn.Close(ctx)
+
+	newConn, err := pgx.ConnectConfig(ctx, newCfg.Copy())
+	if err != nil {
+		return fmt.Errorf("connect destination: %w", err)
+	}
+	defer newConn.Close(ctx)
+
+	for u := range unitCh {
+		if err := copyUnit(ctx, u, oldConn, newConn); err != nil {
+			return fmt.Errorf("copy %s: %w", u.table.Name, err)
+		}
+
+		// each unit is its own checkpoint row, keyed by (table,
+		// chunkStart), so a chunk that crashes mid-table is resumed
+		// individually rather than re-running every chunk of the table
+		// on restart.
+		if err := cw.Advance(ctx, checkpointInitialSync, u.table.Name, u.chunkStart, true); err != nil {
+			return fmt.Errorf("advance checkpoint %s: %w", u.table.Name, err)
+		}
+	}
+	return nil
+}
+
+// copyUnit streams one table (or row range) from the source to the
+// destination using COPY, without holding the rows in memory as JSON.
+func copyUnit(ctx context.Context, u syncUnit, oldConn, newConn *pgx.Conn) error {
+	selectQuery := fmt.Sprintf("select * from %s", u.table.QuotedName())
+	if u.where != "" {
+		selectQuery += " where " + u.where
+	}
+
+	r, w := io.Pipe()
+
+	copyOutErrCh := make(chan error, 1)
+	go func() {
+		_, err := oldConn.PgConn().CopyTo(ctx, w, fmt.Sprintf("copy (%s) to stdout", selectQuery))
+		w.CloseWithError(err)
+		copyOutErrCh <- err
+	}()
+
+	_, err := newConn.PgConn().CopyFrom(ctx, r, fmt.Sprintf("copy %s from stdin", u.table.QuotedName()))
+	r.CloseWithError(err)
+	if copyOutErr := <-copyOutErrCh; err == nil && copyOutErr != nil {
+		err = copyOutErr
+	}
+	if err != nil {
+		return fmt.Errorf("copy rows: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package swo
+
+import "testing"
+
+func TestTableLevel(t *testing.T) {
+	// 0: no deps
+	// 1: depends on 0
+	// 2: depends on 0 and 1
+	deps := [][]int{
+		0: nil,
+		1: {0},
+		2: {0, 1},
+	}
+
+	for i, want := range []int{0, 1, 2} {
+		memo := make([]int, len(deps))
+		lvl, err := tableLevel(i, deps, memo, make(map[int]bool))
+		if err != nil {
+			t.Fatalf("tableLevel(%d) returned error: %v", i, err)
+		}
+		if lvl != want {
+			t.Errorf("tableLevel(%d) = %d, want %d", i, lvl, want)
+		}
+	}
+}
+
+func TestTableLevelCircularDependency(t *testing.T) {
+	// 0 depends on 1, 1 depends on 0
+	deps := [][]int{
+		0: {1},
+		1: {0},
+	}
+
+	memo := make([]int, len(deps))
+	_, err := tableLevel(0, deps, memo, make(map[int]bool))
+	if err == nil {
+		t.Fatal("tableLevel on a circular dependency returned no error")
+	}
+}
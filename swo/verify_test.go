@@ -0,0 +1,15 @@
+package swo
+
+import "testing"
+
+func TestVerifyReportMismatchCount(t *testing.T) {
+	r := &VerifyReport{Tables: []TableVerifyResult{
+		{Table: "alerts", Mismatches: []ChunkMismatch{{Chunk: 0}, {Chunk: 3}}},
+		{Table: "services"},
+		{Table: "users", Mismatches: []ChunkMismatch{{Chunk: 1}}},
+	}}
+
+	if n := r.MismatchCount(); n != 3 {
+		t.Errorf("MismatchCount() = %d, want 3", n)
+	}
+}